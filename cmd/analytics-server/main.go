@@ -0,0 +1,193 @@
+// Command analytics-server runs the analytics API on two transports: an
+// HTTP listener (REST + /openapi.json) and a gRPC listener, both backed by
+// the same analytics.AnalyticsService so behavior never drifts between
+// them.
+//
+// The gRPC server depends on generated stubs from proto/analytics.proto.
+// Regenerate them with:
+//
+//	go generate ./...
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+
+	analyticspb "github.com/Owlumncom/Owlumn/gen/analyticspb"
+	analytics "github.com/Owlumncom/Owlumn/tests/backend"
+)
+
+//go:generate protoc --go_out=. --go-grpc_out=. --proto_path=../../proto ../../proto/analytics.proto
+
+var (
+	httpAddr        = flag.String("http-addr", ":8080", "address for the HTTP listener")
+	grpcAddr        = flag.String("grpc-addr", ":9090", "address for the gRPC listener")
+	schemaDir       = flag.String("schema-dir", "", "directory of *.json event schemas to validate Event.Data against; disabled if empty, mutually exclusive with -schema-url")
+	schemaURL       = flag.String("schema-url", "", "URL serving a JSON schema manifest to validate Event.Data against; disabled if empty, mutually exclusive with -schema-dir")
+	adminToken      = flag.String("schema-admin-token", "", "X-Admin-Token required by /schemas, when schema-dir or schema-url is set")
+	rateLimit       = flag.Int("rate-limit-per-window", 0, "requests allowed per X-API-Key per rate-limit-window; rate limiting disabled if 0")
+	rateLimitWindow = flag.Duration("rate-limit-window", time.Minute, "rate limit window duration")
+)
+
+// grpcServer adapts analytics.AnalyticsService to the generated
+// analyticspb.AnalyticsServiceServer interface.
+type grpcServer struct {
+	analyticspb.UnimplementedAnalyticsServiceServer
+	service analytics.AnalyticsService
+}
+
+// streamAckEvery is how many events TrackEventsStream batches before
+// sending an acknowledgement back to the client.
+const streamAckEvery = 100
+
+func (s *grpcServer) TrackEvent(ctx context.Context, req *analyticspb.Event) (*analyticspb.TrackEventResponse, error) {
+	if err := s.service.TrackEvent(ctx, fromProtoEvent(req)); err != nil {
+		return nil, err
+	}
+	return &analyticspb.TrackEventResponse{Accepted: true}, nil
+}
+
+func (s *grpcServer) GetMetricsReport(ctx context.Context, req *analyticspb.MetricsRequest) (*analyticspb.MetricsResponse, error) {
+	metrics, err := s.service.GetMetricsReport(ctx, req.GetStart().AsTime(), req.GetEnd().AsTime(), req.GetEventType())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &analyticspb.MetricsResponse{Metrics: make([]*analyticspb.Metric, 0, len(metrics))}
+	for _, m := range metrics {
+		resp.Metrics = append(resp.Metrics, &analyticspb.Metric{
+			EventType: m.EventType,
+			Count:     int64(m.Count),
+			Date:      m.Date,
+		})
+	}
+	return resp, nil
+}
+
+// TrackEventsStream accepts a client-side stream of events for
+// high-throughput batch ingest, sending an ack every streamAckEvery events
+// rather than per event.
+func (s *grpcServer) TrackEventsStream(stream analyticspb.AnalyticsService_TrackEventsStreamServer) error {
+	var acked int64
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.service.TrackEvent(stream.Context(), fromProtoEvent(event)); err != nil {
+			return err
+		}
+		acked++
+
+		if acked%streamAckEvery == 0 {
+			if err := stream.Send(&analyticspb.TrackEventsStreamAck{AckedCount: acked}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func fromProtoEvent(e *analyticspb.Event) analytics.Event {
+	return analytics.Event{
+		UserID:    e.GetUserId(),
+		EventType: e.GetEventType(),
+		Timestamp: e.GetTimestamp().AsTime(),
+		Data:      e.GetData(),
+	}
+}
+
+func main() {
+	flag.Parse()
+
+	storage := analytics.NewInMemoryStorage()
+	ingestor := analytics.NewAsyncIngestor(storage, analytics.DefaultIngestorConfig())
+	defer ingestor.Stop()
+
+	handler := analytics.NewAnalyticsHandlerWithIngestor(storage, ingestor)
+
+	switch {
+	case *schemaDir != "" && *schemaURL != "":
+		log.Fatalf("only one of -schema-dir or -schema-url may be set")
+	case *schemaDir != "":
+		registry, err := analytics.NewSchemaRegistry(analytics.DiskSchemaSource{Dir: *schemaDir})
+		if err != nil {
+			log.Fatalf("loading schemas from %s: %v", *schemaDir, err)
+		}
+		registry.WatchSIGHUP()
+		handler.RegisterSchemaRegistry(registry, *adminToken)
+	case *schemaURL != "":
+		registry, err := analytics.NewSchemaRegistry(analytics.URLSchemaSource{URL: *schemaURL})
+		if err != nil {
+			log.Fatalf("loading schemas from %s: %v", *schemaURL, err)
+		}
+		registry.WatchSIGHUP()
+		handler.RegisterSchemaRegistry(registry, *adminToken)
+	}
+
+	// storage only needs to back /funnel and /retention when it implements
+	// FunnelRetentionStorage; otherwise the endpoints stay registered but
+	// respond 501, same as when RegisterFunnelRetentionStorage is never
+	// called at all.
+	if funnelStorage, ok := any(storage).(analytics.FunnelRetentionStorage); ok {
+		handler.RegisterFunnelRetentionStorage(funnelStorage)
+	}
+
+	var rateLimiter *analytics.TokenPool
+	if *rateLimit > 0 {
+		rateLimiter = analytics.NewTokenPool(analytics.NewInMemoryAPIKeyStore(*rateLimit, *rateLimitWindow))
+	}
+
+	// Both transports share this one handler, so a gRPC TrackEvent call
+	// gets the same ingestor batching, sink fan-out and schema validation
+	// as an HTTP POST /track.
+	service := analytics.NewAnalyticsServiceFromHandler(handler)
+
+	go serveHTTP(handler, rateLimiter)
+	serveGRPC(service)
+}
+
+func serveHTTP(handler *analytics.AnalyticsHandler, rateLimiter *analytics.TokenPool) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/track", handler.TrackEvent)
+	mux.HandleFunc("/metrics", handler.GetMetricsReport)
+	mux.HandleFunc("/funnel", handler.GetFunnel)
+	mux.HandleFunc("/retention", handler.GetRetention)
+	mux.HandleFunc("/schemas/", handler.SchemaAdmin)
+	mux.HandleFunc("/openapi.json", analytics.GetApiDocs)
+
+	var root http.Handler = mux
+	if rateLimiter != nil {
+		root = rateLimiter.Middleware(mux)
+	}
+
+	log.Printf("analytics HTTP listening on %s", *httpAddr)
+	if err := http.ListenAndServe(*httpAddr, root); err != nil {
+		log.Fatalf("http server: %v", err)
+	}
+}
+
+func serveGRPC(service analytics.AnalyticsService) {
+	lis, err := net.Listen("tcp", *grpcAddr)
+	if err != nil {
+		log.Fatalf("grpc listen: %v", err)
+	}
+
+	srv := grpc.NewServer()
+	analyticspb.RegisterAnalyticsServiceServer(srv, &grpcServer{service: service})
+
+	log.Printf("analytics gRPC listening on %s", *grpcAddr)
+	if err := srv.Serve(lis); err != nil {
+		log.Fatalf("grpc serve: %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Data structures for analytics
+type Event struct {
+	UserID    string    `json:"user_id"`
+	EventType string    `json:"event_type"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      string    `json:"data"`
+}
+
+type Metric struct {
+	EventType string `json:"event_type"`
+	Count     int    `json:"count"`
+	Date      string `json:"date"`
+}
+
+type AnalyticsStorage interface {
+	SaveEvent(event Event) error
+	GetMetrics(start, end time.Time, eventType string) ([]Metric, error)
+}
+
+type AnalyticsHandler struct {
+	storage         AnalyticsStorage
+	ingestor        *AsyncIngestor
+	sinks           []Sink
+	funnelRetention FunnelRetentionStorage
+	schemaRegistry  *SchemaRegistry
+	adminToken      string
+}
+
+func NewAnalyticsHandler(storage AnalyticsStorage) *AnalyticsHandler {
+	return &AnalyticsHandler{storage: storage}
+}
+
+// NewAnalyticsHandlerWithIngestor wires TrackEvent through an AsyncIngestor
+// instead of writing to storage synchronously on the request goroutine. The
+// ingestor owns its own BatchAnalyticsStorage and is responsible for
+// flushing and shutdown; callers should call ingestor.Stop() during server
+// shutdown to drain any buffered events. storage is used for reads
+// (GetMetricsReport) and should be the same backing store the ingestor
+// flushes into.
+func NewAnalyticsHandlerWithIngestor(storage AnalyticsStorage, ingestor *AsyncIngestor) *AnalyticsHandler {
+	return &AnalyticsHandler{storage: storage, ingestor: ingestor}
+}
+
+// ValidationFailedError is returned by trackEvent when the event's data
+// fails, or can't be matched against, its registered schema.
+type ValidationFailedError struct {
+	Errs []ValidationError
+}
+
+func (e *ValidationFailedError) Error() string {
+	return "event failed schema validation"
+}
+
+// trackEvent is the transport-agnostic core of TrackEvent: schema
+// validation, then ingestor-or-storage dispatch and sink fan-out. Both the
+// HTTP handler below and the AnalyticsService adapter in grpc_openapi.go
+// call this, so gRPC requests get the same validation, batching and sinks
+// as HTTP ones instead of a separate bypass path.
+func (h *AnalyticsHandler) trackEvent(ctx context.Context, event Event) error {
+	if h.schemaRegistry != nil {
+		validationErrs, err := h.schemaRegistry.Validate(event.EventType, []byte(event.Data))
+		if err != nil {
+			return &ValidationFailedError{Errs: []ValidationError{{Field: "data", Message: "event data is not valid JSON"}}}
+		}
+		if len(validationErrs) > 0 {
+			return &ValidationFailedError{Errs: validationErrs}
+		}
+	}
+
+	event.Timestamp = time.Now()
+
+	if h.ingestor != nil {
+		if err := h.ingestor.Enqueue(event); err != nil {
+			return err
+		}
+		go h.publishToSinks(event)
+		return nil
+	}
+
+	if err := h.storage.SaveEvent(event); err != nil {
+		return err
+	}
+	go h.publishToSinks(event)
+	return nil
+}
+
+// getMetricsReport is the transport-agnostic core of GetMetricsReport.
+func (h *AnalyticsHandler) getMetricsReport(ctx context.Context, start, end time.Time, eventType string) ([]Metric, error) {
+	return h.storage.GetMetrics(start, end, eventType)
+}
+
+func (h *AnalyticsHandler) TrackEvent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var event Event
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if event.UserID == "" || event.EventType == "" {
+		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		return
+	}
+
+	switch err := h.trackEvent(r.Context(), event).(type) {
+	case nil:
+		if h.ingestor != nil {
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"status":"accepted"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"success"}`))
+	case *ValidationFailedError:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": err.Errs})
+	default:
+		if err == ErrIngestorFull {
+			// The ingestor never actually took the event, so the token
+			// TokenPool.Middleware checked out for this request wasn't
+			// spent; report it unchanged so the pool refunds it.
+			if token := TokenFromContext(r.Context()); token != nil {
+				ReportActualRemaining(r.Context(), token.Remaining)
+			}
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		http.Error(w, "Failed to save event", http.StatusInternalServerError)
+	}
+}
+
+func (h *AnalyticsHandler) GetMetricsReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startStr := r.URL.Query().Get("start")
+	endStr := r.URL.Query().Get("end")
+	eventType := r.URL.Query().Get("event_type")
+
+	start, err := time.Parse("2006-01-02", startStr)
+	if err != nil {
+		http.Error(w, "Invalid start date", http.StatusBadRequest)
+		return
+	}
+
+	end, err := time.Parse("2006-01-02", endStr)
+	if err != nil {
+		http.Error(w, "Invalid end date", http.StatusBadRequest)
+		return
+	}
+
+	metrics, err := h.getMetricsReport(r.Context(), start, end, eventType)
+	if err != nil {
+		http.Error(w, "Failed to fetch metrics", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metrics)
+}
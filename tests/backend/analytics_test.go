@@ -1,10 +1,11 @@
-package analytics  
+package analytics
 
 import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -32,98 +33,25 @@ func (m *MockAnalyticsStorage) EXPECT() *MockAnalyticsStorageMockRecorder {
 	return m.recorder
 }
 
-func (m *MockAnalyticsStorageMockRecorder) SaveEvent(event Event) *gomock.Call {
-	return m.mock.ctrl.RecordCall(m.mock, "SaveEvent", event)
-}
-
-func (m *MockAnalyticsStorageMockRecorder) GetMetrics(start, end time.Time, eventType string) ([]Metric, error) {
-	return m.mock.ctrl.RecordCallWithMethodType(m.mock, "GetMetrics", reflect.TypeOf((*MockAnalyticsStorage)(nil).GetMetrics), start, end, eventType)
-}
-
-// Data structures for analytics
-type Event struct {
-	UserID    string    `json:"user_id"`
-	EventType string    `json:"event_type"`
-	Timestamp time.Time `json:"timestamp"`
-	Data      string    `json:"data"`
-}
-
-type Metric struct {
-	EventType string `json:"event_type"`
-	Count     int    `json:"count"`
-	Date      string `json:"date"`
+func (m *MockAnalyticsStorage) SaveEvent(event Event) error {
+	results := m.ctrl.Call(m, "SaveEvent", event)
+	err, _ := results[0].(error)
+	return err
 }
 
-type AnalyticsStorage interface {
-	SaveEvent(event Event) error
-	GetMetrics(start, end time.Time, eventType string) ([]Metric, error)
+func (m *MockAnalyticsStorage) GetMetrics(start, end time.Time, eventType string) ([]Metric, error) {
+	results := m.ctrl.Call(m, "GetMetrics", start, end, eventType)
+	metrics, _ := results[0].([]Metric)
+	err, _ := results[1].(error)
+	return metrics, err
 }
 
-type AnalyticsHandler struct {
-	storage AnalyticsStorage
-}
-
-func NewAnalyticsHandler(storage AnalyticsStorage) *AnalyticsHandler {
-	return &AnalyticsHandler{storage: storage}
-}
-
-func (h *AnalyticsHandler) TrackEvent(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	var event Event
-	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	if event.UserID == "" || event.EventType == "" {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
-		return
-	}
-
-	event.Timestamp = time.Now()
-	if err := h.storage.SaveEvent(event); err != nil {
-		http.Error(w, "Failed to save event", http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status":"success"}`))
+func (m *MockAnalyticsStorageMockRecorder) SaveEvent(event interface{}) *gomock.Call {
+	return m.mock.ctrl.RecordCall(m.mock, "SaveEvent", event)
 }
 
-func (h *AnalyticsHandler) GetMetricsReport(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	startStr := r.URL.Query().Get("start")
-	endStr := r.URL.Query().Get("end")
-	eventType := r.URL.Query().Get("event_type")
-
-	start, err := time.Parse("2006-01-02", startStr)
-	if err != nil {
-		http.Error(w, "Invalid start date", http.StatusBadRequest)
-		return
-	}
-
-	end, err := time.Parse("2006-01-02", endStr)
-	if err != nil {
-		http.Error(w, "Invalid end date", http.StatusBadRequest)
-		return
-	}
-
-	metrics, err := h.storage.GetMetrics(start, end, eventType)
-	if err != nil {
-		http.Error(w, "Failed to fetch metrics", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(metrics)
+func (m *MockAnalyticsStorageMockRecorder) GetMetrics(start, end, eventType interface{}) *gomock.Call {
+	return m.mock.ctrl.RecordCall(m.mock, "GetMetrics", start, end, eventType)
 }
 
 // Test suite for Analytics functionality
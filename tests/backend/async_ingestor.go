@@ -0,0 +1,153 @@
+package analytics
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrIngestorFull is returned by AsyncIngestor.Enqueue when the ring buffer
+// has reached its bound and the caller should back off.
+var ErrIngestorFull = errors.New("async ingestor: buffer full")
+
+// BatchAnalyticsStorage is implemented by storage backends that can persist
+// many events in a single call. It sits alongside AnalyticsStorage rather
+// than replacing it so existing synchronous callers keep working.
+type BatchAnalyticsStorage interface {
+	SaveEvents(events []Event) error
+}
+
+// IngestorConfig controls how an AsyncIngestor buffers and flushes events.
+type IngestorConfig struct {
+	// BufferSize is the capacity of the bounded ring buffer. Enqueue
+	// returns ErrIngestorFull once this many events are pending flush.
+	BufferSize int
+	// FlushSize triggers an immediate flush once this many events have
+	// accumulated.
+	FlushSize int
+	// FlushInterval triggers a flush on a timer even if FlushSize has not
+	// been reached, bounding end-to-end latency for low-traffic periods.
+	FlushInterval time.Duration
+}
+
+// DefaultIngestorConfig matches the batching thresholds most callers want:
+// flush every 500 events or every second, whichever comes first.
+func DefaultIngestorConfig() IngestorConfig {
+	return IngestorConfig{
+		BufferSize:    5000,
+		FlushSize:     500,
+		FlushInterval: time.Second,
+	}
+}
+
+// AsyncIngestor buffers events in memory and flushes them to a
+// BatchAnalyticsStorage in batches, off the request goroutine. It applies
+// backpressure once the buffer fills rather than growing unbounded.
+type AsyncIngestor struct {
+	storage BatchAnalyticsStorage
+	cfg     IngestorConfig
+
+	mu      sync.Mutex
+	buf     []Event
+	flushCh chan struct{}
+	done    chan struct{}
+	closed  chan struct{}
+	once    sync.Once
+}
+
+// NewAsyncIngestor starts the background flush loop and returns a ready to
+// use ingestor. Callers must call Stop to drain buffered events on
+// shutdown.
+func NewAsyncIngestor(storage BatchAnalyticsStorage, cfg IngestorConfig) *AsyncIngestor {
+	a := &AsyncIngestor{
+		storage: storage,
+		cfg:     cfg,
+		buf:     make([]Event, 0, cfg.FlushSize),
+		flushCh: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		closed:  make(chan struct{}),
+	}
+	go a.loop()
+	return a
+}
+
+// Enqueue adds an event to the buffer, returning ErrIngestorFull if the
+// buffer is at capacity so the caller can apply backpressure to its client.
+// It never calls into storage itself: crossing FlushSize only nudges the
+// background loop to flush, so the caller's goroutine can't be made to
+// wait on a SaveEvents call.
+func (a *AsyncIngestor) Enqueue(event Event) error {
+	a.mu.Lock()
+	if len(a.buf) >= a.cfg.BufferSize {
+		a.mu.Unlock()
+		return ErrIngestorFull
+	}
+	a.buf = append(a.buf, event)
+	reachedFlushSize := len(a.buf) >= a.cfg.FlushSize
+	a.mu.Unlock()
+
+	if reachedFlushSize {
+		select {
+		case a.flushCh <- struct{}{}:
+		default:
+			// A flush is already pending; the loop will pick up this
+			// event on that pass.
+		}
+	}
+	return nil
+}
+
+// Stop drains any buffered events with a final flush and stops the
+// background flush loop. It is safe to call Stop more than once.
+func (a *AsyncIngestor) Stop() {
+	a.once.Do(func() {
+		close(a.done)
+		<-a.closed
+	})
+}
+
+func (a *AsyncIngestor) loop() {
+	defer close(a.closed)
+
+	// A non-positive FlushInterval disables the periodic flush entirely;
+	// callers that only want size-triggered flushing pass 0 rather than an
+	// arbitrarily large duration.
+	var tickerC <-chan time.Time
+	if a.cfg.FlushInterval > 0 {
+		ticker := time.NewTicker(a.cfg.FlushInterval)
+		defer ticker.Stop()
+		tickerC = ticker.C
+	}
+
+	for {
+		select {
+		case <-tickerC:
+			a.flush()
+		case <-a.flushCh:
+			a.flush()
+		case <-a.done:
+			a.flush()
+			return
+		}
+	}
+}
+
+// flush swaps out the buffered events under the lock, then calls
+// SaveEvents outside of it so a slow or blocked storage write never holds
+// up Enqueue on another goroutine. It is a no-op when the buffer is empty
+// so the periodic ticker doesn't issue empty storage calls.
+func (a *AsyncIngestor) flush() {
+	a.mu.Lock()
+	if len(a.buf) == 0 {
+		a.mu.Unlock()
+		return
+	}
+	batch := a.buf
+	a.buf = make([]Event, 0, a.cfg.FlushSize)
+	a.mu.Unlock()
+
+	// Storage errors are swallowed here rather than surfaced to the
+	// original caller, who has already received a 202 Accepted. A future
+	// iteration can route failed batches to a dead-letter sink.
+	_ = a.storage.SaveEvents(batch)
+}
@@ -0,0 +1,245 @@
+package analytics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockBatchAnalyticsStorage is a hand-rolled gomock-style mock following the
+// same pattern as MockAnalyticsStorage above.
+type mockBatchAnalyticsStorage struct {
+	ctrl     *gomock.Controller
+	recorder *mockBatchAnalyticsStorageRecorder
+}
+
+type mockBatchAnalyticsStorageRecorder struct {
+	mock *mockBatchAnalyticsStorage
+}
+
+func newMockBatchAnalyticsStorage(ctrl *gomock.Controller) *mockBatchAnalyticsStorage {
+	mock := &mockBatchAnalyticsStorage{ctrl: ctrl}
+	mock.recorder = &mockBatchAnalyticsStorageRecorder{mock}
+	return mock
+}
+
+func (m *mockBatchAnalyticsStorage) EXPECT() *mockBatchAnalyticsStorageRecorder {
+	return m.recorder
+}
+
+func (m *mockBatchAnalyticsStorage) SaveEvents(events []Event) error {
+	results := m.ctrl.Call(m, "SaveEvents", events)
+	err, _ := results[0].(error)
+	return err
+}
+
+func (m *mockBatchAnalyticsStorageRecorder) SaveEvents(events interface{}) *gomock.Call {
+	return m.mock.ctrl.RecordCall(m.mock, "SaveEvents", events)
+}
+
+func TestAsyncIngestor_FlushesOnSizeThreshold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	flushed := make(chan []Event, 1)
+	mockStorage := newMockBatchAnalyticsStorage(ctrl)
+	mockStorage.EXPECT().SaveEvents(gomock.Any()).DoAndReturn(func(events []Event) error {
+		flushed <- events
+		return nil
+	}).Times(1)
+
+	cfg := IngestorConfig{BufferSize: 10, FlushSize: 3, FlushInterval: time.Minute}
+	ingestor := NewAsyncIngestor(mockStorage, cfg)
+	defer ingestor.Stop()
+
+	for i := 0; i < 3; i++ {
+		err := ingestor.Enqueue(Event{UserID: "u1", EventType: "login"})
+		assert.NoError(t, err)
+	}
+
+	select {
+	case events := <-flushed:
+		assert.Len(t, events, 3)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for size-threshold flush")
+	}
+}
+
+func TestAsyncIngestor_FlushesOnInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	flushed := make(chan []Event, 1)
+	mockStorage := newMockBatchAnalyticsStorage(ctrl)
+	mockStorage.EXPECT().SaveEvents(gomock.Any()).DoAndReturn(func(events []Event) error {
+		flushed <- events
+		return nil
+	}).Times(1)
+
+	cfg := IngestorConfig{BufferSize: 10, FlushSize: 500, FlushInterval: 5 * time.Millisecond}
+	ingestor := NewAsyncIngestor(mockStorage, cfg)
+	defer ingestor.Stop()
+
+	err := ingestor.Enqueue(Event{UserID: "u1", EventType: "login"})
+	assert.NoError(t, err)
+
+	select {
+	case events := <-flushed:
+		assert.Len(t, events, 1)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for interval-triggered flush")
+	}
+}
+
+// TestAsyncIngestor_ConcurrentEnqueue exercises batching under real
+// concurrent load, firing goroutines the same way
+// TestAnalyticsHandler_TrackEvent_ConcurrentRequests does, and uses a
+// channel signal from SaveEvents instead of a sleep to know when every
+// enqueued event has landed in storage.
+func TestAsyncIngestor_ConcurrentEnqueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const producers = 50
+
+	var mu sync.Mutex
+	var flushedCount int
+	allFlushed := make(chan struct{})
+	var closeOnce sync.Once
+
+	mockStorage := newMockBatchAnalyticsStorage(ctrl)
+	mockStorage.EXPECT().SaveEvents(gomock.Any()).DoAndReturn(func(events []Event) error {
+		mu.Lock()
+		flushedCount += len(events)
+		done := flushedCount >= producers
+		mu.Unlock()
+		if done {
+			closeOnce.Do(func() { close(allFlushed) })
+		}
+		return nil
+	}).MinTimes(1)
+
+	cfg := IngestorConfig{BufferSize: producers, FlushSize: 10, FlushInterval: 5 * time.Millisecond}
+	ingestor := NewAsyncIngestor(mockStorage, cfg)
+	defer ingestor.Stop()
+
+	var wg sync.WaitGroup
+	wg.Add(producers)
+	for i := 0; i < producers; i++ {
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, ingestor.Enqueue(Event{UserID: "u1", EventType: "login"}))
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case <-allFlushed:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for concurrently enqueued events to flush")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, producers, flushedCount)
+}
+
+func TestAsyncIngestor_BackpressureWhenFull(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := newMockBatchAnalyticsStorage(ctrl)
+	mockStorage.EXPECT().SaveEvents(gomock.Any()).Return(nil).AnyTimes()
+
+	cfg := IngestorConfig{BufferSize: 2, FlushSize: 1000, FlushInterval: time.Minute}
+	ingestor := NewAsyncIngestor(mockStorage, cfg)
+	defer ingestor.Stop()
+
+	assert.NoError(t, ingestor.Enqueue(Event{UserID: "u1", EventType: "login"}))
+	assert.NoError(t, ingestor.Enqueue(Event{UserID: "u2", EventType: "login"}))
+
+	err := ingestor.Enqueue(Event{UserID: "u3", EventType: "login"})
+	assert.Equal(t, ErrIngestorFull, err)
+}
+
+func TestAsyncIngestor_DrainsOnStop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := newMockBatchAnalyticsStorage(ctrl)
+	mockStorage.EXPECT().SaveEvents(gomock.Any()).Return(nil).Times(1)
+
+	cfg := IngestorConfig{BufferSize: 10, FlushSize: 500, FlushInterval: time.Minute}
+	ingestor := NewAsyncIngestor(mockStorage, cfg)
+
+	err := ingestor.Enqueue(Event{UserID: "u1", EventType: "login"})
+	assert.NoError(t, err)
+
+	ingestor.Stop()
+}
+
+func TestAnalyticsHandler_TrackEvent_AsyncAccepted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := newMockBatchAnalyticsStorage(ctrl)
+	mockStorage.EXPECT().SaveEvents(gomock.Any()).Return(nil).AnyTimes()
+
+	cfg := IngestorConfig{BufferSize: 10, FlushSize: 5, FlushInterval: time.Minute}
+	ingestor := NewAsyncIngestor(mockStorage, cfg)
+	defer ingestor.Stop()
+
+	handler := NewAnalyticsHandlerWithIngestor(nil, ingestor)
+	server := httptest.NewServer(http.HandlerFunc(handler.TrackEvent))
+	defer server.Close()
+
+	event := Event{UserID: "user123", EventType: "login", Data: "test_data"}
+	body, _ := json.Marshal(event)
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(string(body)))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+}
+
+func TestAnalyticsHandler_TrackEvent_AsyncBackpressure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := newMockBatchAnalyticsStorage(ctrl)
+	mockStorage.EXPECT().SaveEvents(gomock.Any()).Return(nil).AnyTimes()
+
+	cfg := IngestorConfig{BufferSize: 1, FlushSize: 1000, FlushInterval: time.Minute}
+	ingestor := NewAsyncIngestor(mockStorage, cfg)
+	defer ingestor.Stop()
+
+	handler := NewAnalyticsHandlerWithIngestor(nil, ingestor)
+	server := httptest.NewServer(http.HandlerFunc(handler.TrackEvent))
+	defer server.Close()
+
+	event := Event{UserID: "user123", EventType: "login", Data: "test_data"}
+	body, _ := json.Marshal(event)
+
+	// First request fills the single buffer slot.
+	resp1, err := http.Post(server.URL, "application/json", strings.NewReader(string(body)))
+	assert.NoError(t, err)
+	resp1.Body.Close()
+
+	// Second request should be rejected with backpressure: FlushSize is
+	// far above BufferSize and FlushInterval is a minute, so nothing
+	// drains the single buffered event before this call lands.
+	resp2, err := http.Post(server.URL, "application/json", strings.NewReader(string(body)))
+	assert.NoError(t, err)
+	defer resp2.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp2.StatusCode)
+	assert.Equal(t, "1", resp2.Header.Get("Retry-After"))
+}
@@ -0,0 +1,191 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FunnelStepResult is the completion count and conversion rate for a single
+// step of a funnel, relative to the first step.
+type FunnelStepResult struct {
+	Step           string  `json:"step"`
+	Count          int     `json:"count"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+// FunnelReport is the per-step breakdown returned by GetFunnel.
+type FunnelReport struct {
+	Steps []FunnelStepResult `json:"steps"`
+}
+
+// RetentionCohort is a single first-seen-date cohort and the percentage of
+// that cohort that returned in each subsequent bucket.
+type RetentionCohort struct {
+	CohortDate string    `json:"cohort_date"`
+	CohortSize int       `json:"cohort_size"`
+	Returning  []float64 `json:"returning"`
+}
+
+// RetentionMatrix is the full set of cohorts returned by GetRetention.
+type RetentionMatrix struct {
+	Buckets []string          `json:"buckets"`
+	Cohorts []RetentionCohort `json:"cohorts"`
+}
+
+// FunnelRetentionStorage is implemented by storage backends that can
+// compute ordered step completion and cohort return rates. It sits
+// alongside AnalyticsStorage the same way BatchAnalyticsStorage does, so
+// backends that don't support these queries aren't forced to implement
+// them.
+type FunnelRetentionStorage interface {
+	GetFunnel(steps []string, start, end time.Time, window time.Duration) (FunnelReport, error)
+	GetRetention(cohortEvent, returnEvent string, start, end time.Time, bucket string) (RetentionMatrix, error)
+}
+
+// RegisterFunnelRetentionStorage wires the /funnel and /retention endpoints
+// to a backend. Leaving it unset makes both endpoints respond 501.
+func (h *AnalyticsHandler) RegisterFunnelRetentionStorage(storage FunnelRetentionStorage) {
+	h.funnelRetention = storage
+}
+
+// GetFunnel serves GET /funnel?steps=a,b,c&start=...&end=...&window=1h
+func (h *AnalyticsHandler) GetFunnel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.funnelRetention == nil {
+		http.Error(w, "Funnel queries not configured", http.StatusNotImplemented)
+		return
+	}
+
+	steps, start, end, err := parseStepQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	window := time.Hour
+	if raw := r.URL.Query().Get("window"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			http.Error(w, "Invalid window", http.StatusBadRequest)
+			return
+		}
+		window = parsed
+	}
+
+	report, err := h.funnelRetention.GetFunnel(steps, start, end, window)
+	if err != nil {
+		http.Error(w, "Failed to compute funnel", http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiated(w, r, funnelToCSV(report), report)
+}
+
+// GetRetention serves GET /retention?cohort_event=...&return_event=...&start=...&end=...&bucket=week
+func (h *AnalyticsHandler) GetRetention(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.funnelRetention == nil {
+		http.Error(w, "Retention queries not configured", http.StatusNotImplemented)
+		return
+	}
+
+	q := r.URL.Query()
+	cohortEvent := q.Get("cohort_event")
+	returnEvent := q.Get("return_event")
+	bucket := q.Get("bucket")
+	if cohortEvent == "" || returnEvent == "" || bucket == "" {
+		http.Error(w, "Missing required query parameters", http.StatusBadRequest)
+		return
+	}
+
+	start, err := time.Parse("2006-01-02", q.Get("start"))
+	if err != nil {
+		http.Error(w, "Invalid start date", http.StatusBadRequest)
+		return
+	}
+	end, err := time.Parse("2006-01-02", q.Get("end"))
+	if err != nil {
+		http.Error(w, "Invalid end date", http.StatusBadRequest)
+		return
+	}
+
+	matrix, err := h.funnelRetention.GetRetention(cohortEvent, returnEvent, start, end, bucket)
+	if err != nil {
+		http.Error(w, "Failed to compute retention", http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiated(w, r, retentionToCSV(matrix), matrix)
+}
+
+func parseStepQuery(r *http.Request) (steps []string, start, end time.Time, err error) {
+	q := r.URL.Query()
+	raw := q.Get("steps")
+	if raw == "" {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("missing steps parameter")
+	}
+	for _, s := range strings.Split(raw, ",") {
+		steps = append(steps, strings.TrimSpace(s))
+	}
+
+	start, err = time.Parse("2006-01-02", q.Get("start"))
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("invalid start date")
+	}
+	end, err = time.Parse("2006-01-02", q.Get("end"))
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("invalid end date")
+	}
+	return steps, start, end, nil
+}
+
+// writeNegotiated responds with CSV when the request's Accept header asks
+// for text/csv, and JSON otherwise.
+func writeNegotiated(w http.ResponseWriter, r *http.Request, csvBody []byte, jsonBody interface{}) {
+	if r.Header.Get("Accept") == "text/csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write(csvBody)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jsonBody)
+}
+
+func funnelToCSV(report FunnelReport) []byte {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	cw.Write([]string{"step", "count", "conversion_rate"})
+	for _, s := range report.Steps {
+		cw.Write([]string{s.Step, strconv.Itoa(s.Count), strconv.FormatFloat(s.ConversionRate, 'f', 4, 64)})
+	}
+	cw.Flush()
+	return buf.Bytes()
+}
+
+func retentionToCSV(matrix RetentionMatrix) []byte {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	header := append([]string{"cohort_date", "cohort_size"}, matrix.Buckets...)
+	cw.Write(header)
+	for _, c := range matrix.Cohorts {
+		row := []string{c.CohortDate, strconv.Itoa(c.CohortSize)}
+		for _, pct := range c.Returning {
+			row = append(row, strconv.FormatFloat(pct, 'f', 4, 64))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return buf.Bytes()
+}
@@ -0,0 +1,156 @@
+package analytics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockFunnelRetentionStorage is a hand-rolled gomock-style mock matching
+// the pattern used for MockAnalyticsStorage elsewhere in this package.
+type mockFunnelRetentionStorage struct {
+	ctrl     *gomock.Controller
+	recorder *mockFunnelRetentionStorageRecorder
+}
+
+type mockFunnelRetentionStorageRecorder struct {
+	mock *mockFunnelRetentionStorage
+}
+
+func newMockFunnelRetentionStorage(ctrl *gomock.Controller) *mockFunnelRetentionStorage {
+	mock := &mockFunnelRetentionStorage{ctrl: ctrl}
+	mock.recorder = &mockFunnelRetentionStorageRecorder{mock}
+	return mock
+}
+
+func (m *mockFunnelRetentionStorage) EXPECT() *mockFunnelRetentionStorageRecorder {
+	return m.recorder
+}
+
+func (m *mockFunnelRetentionStorage) GetFunnel(steps []string, start, end time.Time, window time.Duration) (FunnelReport, error) {
+	results := m.ctrl.Call(m, "GetFunnel", steps, start, end, window)
+	report, _ := results[0].(FunnelReport)
+	err, _ := results[1].(error)
+	return report, err
+}
+
+func (m *mockFunnelRetentionStorageRecorder) GetFunnel(steps, start, end, window interface{}) *gomock.Call {
+	return m.mock.ctrl.RecordCall(m.mock, "GetFunnel", steps, start, end, window)
+}
+
+func (m *mockFunnelRetentionStorage) GetRetention(cohortEvent, returnEvent string, start, end time.Time, bucket string) (RetentionMatrix, error) {
+	results := m.ctrl.Call(m, "GetRetention", cohortEvent, returnEvent, start, end, bucket)
+	matrix, _ := results[0].(RetentionMatrix)
+	err, _ := results[1].(error)
+	return matrix, err
+}
+
+func (m *mockFunnelRetentionStorageRecorder) GetRetention(cohortEvent, returnEvent, start, end, bucket interface{}) *gomock.Call {
+	return m.mock.ctrl.RecordCall(m.mock, "GetRetention", cohortEvent, returnEvent, start, end, bucket)
+}
+
+func TestAnalyticsHandler_GetFunnel_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := NewMockAnalyticsStorage(ctrl)
+	mockFunnel := newMockFunnelRetentionStorage(ctrl)
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)
+	report := FunnelReport{Steps: []FunnelStepResult{
+		{Step: "signup", Count: 100, ConversionRate: 1.0},
+		{Step: "activate", Count: 40, ConversionRate: 0.4},
+	}}
+	mockFunnel.EXPECT().GetFunnel([]string{"signup", "activate"}, start, end, time.Hour).Return(report, nil)
+
+	handler := NewAnalyticsHandler(mockStorage)
+	handler.RegisterFunnelRetentionStorage(mockFunnel)
+
+	server := httptest.NewServer(http.HandlerFunc(handler.GetFunnel))
+	defer server.Close()
+
+	url := server.URL + "?steps=signup,activate&start=2023-01-01&end=2023-01-31"
+	resp, err := http.Get(url)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var got FunnelReport
+	json.NewDecoder(resp.Body).Decode(&got)
+	assert.Equal(t, report, got)
+}
+
+func TestAnalyticsHandler_GetFunnel_NotConfigured(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := NewMockAnalyticsStorage(ctrl)
+	handler := NewAnalyticsHandler(mockStorage)
+
+	server := httptest.NewServer(http.HandlerFunc(handler.GetFunnel))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?steps=signup&start=2023-01-01&end=2023-01-31")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+}
+
+func TestAnalyticsHandler_GetRetention_CSVNegotiation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := NewMockAnalyticsStorage(ctrl)
+	mockFunnel := newMockFunnelRetentionStorage(ctrl)
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)
+	matrix := RetentionMatrix{
+		Buckets: []string{"week_1", "week_2"},
+		Cohorts: []RetentionCohort{
+			{CohortDate: "2023-01-01", CohortSize: 100, Returning: []float64{0.5, 0.3}},
+		},
+	}
+	mockFunnel.EXPECT().GetRetention("signup", "login", start, end, "week").Return(matrix, nil)
+
+	handler := NewAnalyticsHandler(mockStorage)
+	handler.RegisterFunnelRetentionStorage(mockFunnel)
+
+	server := httptest.NewServer(http.HandlerFunc(handler.GetRetention))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"?cohort_event=signup&return_event=login&start=2023-01-01&end=2023-01-31&bucket=week", nil)
+	req.Header.Set("Accept", "text/csv")
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/csv", resp.Header.Get("Content-Type"))
+}
+
+func TestAnalyticsHandler_GetRetention_MissingParams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := NewMockAnalyticsStorage(ctrl)
+	mockFunnel := newMockFunnelRetentionStorage(ctrl)
+
+	handler := NewAnalyticsHandler(mockStorage)
+	handler.RegisterFunnelRetentionStorage(mockFunnel)
+
+	server := httptest.NewServer(http.HandlerFunc(handler.GetRetention))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?cohort_event=signup")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
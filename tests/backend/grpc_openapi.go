@@ -0,0 +1,328 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// AnalyticsService is the transport-agnostic core of the analytics API.
+// The gRPC server in cmd/analytics-server calls into the same
+// implementation HTTP uses, so validation, batching and storage semantics
+// only live in one place.
+type AnalyticsService interface {
+	TrackEvent(ctx context.Context, event Event) error
+	GetMetricsReport(ctx context.Context, start, end time.Time, eventType string) ([]Metric, error)
+}
+
+// handlerAnalyticsService adapts an AnalyticsHandler into the
+// transport-agnostic AnalyticsService interface, so gRPC callers get the
+// same batching, fan-out and validation HTTP callers do rather than a
+// plain storage write.
+type handlerAnalyticsService struct {
+	handler *AnalyticsHandler
+}
+
+// NewAnalyticsServiceFromHandler wraps handler's TrackEvent/GetMetricsReport
+// logic as an AnalyticsService, so cmd/analytics-server's gRPC server and
+// HTTP mux can share one fully configured AnalyticsHandler instead of the
+// gRPC side bypassing its ingestor, sinks and schema registry.
+func NewAnalyticsServiceFromHandler(handler *AnalyticsHandler) AnalyticsService {
+	return &handlerAnalyticsService{handler: handler}
+}
+
+func (s *handlerAnalyticsService) TrackEvent(ctx context.Context, event Event) error {
+	return s.handler.trackEvent(ctx, event)
+}
+
+func (s *handlerAnalyticsService) GetMetricsReport(ctx context.Context, start, end time.Time, eventType string) ([]Metric, error) {
+	return s.handler.getMetricsReport(ctx, start, end, eventType)
+}
+
+// InMemoryStorage is a minimal AnalyticsStorage backed by an in-process
+// slice, suitable for cmd/analytics-server's default configuration and for
+// local development without a real database.
+type InMemoryStorage struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func NewInMemoryStorage() *InMemoryStorage {
+	return &InMemoryStorage{}
+}
+
+func (s *InMemoryStorage) SaveEvent(event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+// SaveEvents satisfies BatchAnalyticsStorage so InMemoryStorage can back an
+// AsyncIngestor directly.
+func (s *InMemoryStorage) SaveEvents(events []Event) error {
+	for _, e := range events {
+		if err := s.SaveEvent(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *InMemoryStorage) GetMetrics(start, end time.Time, eventType string) ([]Metric, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int)
+	for _, e := range s.events {
+		if eventType != "" && e.EventType != eventType {
+			continue
+		}
+		if e.Timestamp.Before(start) || e.Timestamp.After(end) {
+			continue
+		}
+		counts[e.Timestamp.Format("2006-01-02")]++
+	}
+
+	metrics := make([]Metric, 0, len(counts))
+	for date, count := range counts {
+		metrics = append(metrics, Metric{EventType: eventType, Count: count, Date: date})
+	}
+	return metrics, nil
+}
+
+// bucketDuration maps the retention "bucket" query parameter to the
+// duration of a single bucket.
+func bucketDuration(bucket string) (time.Duration, error) {
+	switch bucket {
+	case "day":
+		return 24 * time.Hour, nil
+	case "week":
+		return 7 * 24 * time.Hour, nil
+	case "month":
+		return 30 * 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("unsupported bucket %q", bucket)
+	}
+}
+
+// GetFunnel satisfies FunnelRetentionStorage by walking each user's events,
+// in timestamp order, looking for an ordered completion of steps: step i+1
+// must occur no later than window after step i. A user who never completes
+// step i doesn't count toward step i+1 or beyond.
+func (s *InMemoryStorage) GetFunnel(steps []string, start, end time.Time, window time.Duration) (FunnelReport, error) {
+	if len(steps) == 0 {
+		return FunnelReport{}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	eventsByUser := make(map[string][]Event)
+	for _, e := range s.events {
+		if e.Timestamp.Before(start) || e.Timestamp.After(end) {
+			continue
+		}
+		eventsByUser[e.UserID] = append(eventsByUser[e.UserID], e)
+	}
+	for _, events := range eventsByUser {
+		sort.Slice(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+	}
+
+	counts := make([]int, len(steps))
+	for _, events := range eventsByUser {
+		var last time.Time
+		pos := -1
+		reached := 0
+		for i, step := range steps {
+			found := false
+			for j := pos + 1; j < len(events); j++ {
+				if events[j].EventType != step {
+					continue
+				}
+				if i > 0 && events[j].Timestamp.Sub(last) > window {
+					continue
+				}
+				pos, last = j, events[j].Timestamp
+				found = true
+				break
+			}
+			if !found {
+				break
+			}
+			reached = i + 1
+		}
+		for i := 0; i < reached; i++ {
+			counts[i]++
+		}
+	}
+
+	result := FunnelReport{Steps: make([]FunnelStepResult, len(steps))}
+	base := counts[0]
+	for i, step := range steps {
+		var rate float64
+		if base > 0 {
+			rate = float64(counts[i]) / float64(base)
+		}
+		result.Steps[i] = FunnelStepResult{Step: step, Count: counts[i], ConversionRate: rate}
+	}
+	return result, nil
+}
+
+// GetRetention satisfies FunnelRetentionStorage by bucketing each user into
+// a cohort keyed by the bucket containing their first cohortEvent, then
+// measuring, for each bucket offset after that, what fraction of the
+// cohort logged a returnEvent in that offset's bucket.
+func (s *InMemoryStorage) GetRetention(cohortEvent, returnEvent string, start, end time.Time, bucket string) (RetentionMatrix, error) {
+	dur, err := bucketDuration(bucket)
+	if err != nil {
+		return RetentionMatrix{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	firstSeen := make(map[string]time.Time)
+	returns := make(map[string][]time.Time)
+	for _, e := range s.events {
+		if e.Timestamp.Before(start) || e.Timestamp.After(end) {
+			continue
+		}
+		switch e.EventType {
+		case cohortEvent:
+			if t, ok := firstSeen[e.UserID]; !ok || e.Timestamp.Before(t) {
+				firstSeen[e.UserID] = e.Timestamp
+			}
+		case returnEvent:
+			returns[e.UserID] = append(returns[e.UserID], e.Timestamp)
+		}
+	}
+
+	periods := int(end.Sub(start)/dur) + 1
+	buckets := make([]string, periods)
+	for i := range buckets {
+		buckets[i] = strconv.Itoa(i)
+	}
+
+	type cohort struct {
+		users     int
+		returning []int
+	}
+	cohorts := make(map[string]*cohort)
+	cohortStart := make(map[string]time.Time)
+
+	for user, seenAt := range firstSeen {
+		cohortDate := seenAt.Truncate(dur)
+		key := cohortDate.Format("2006-01-02")
+		c, ok := cohorts[key]
+		if !ok {
+			c = &cohort{returning: make([]int, periods)}
+			cohorts[key] = c
+			cohortStart[key] = cohortDate
+		}
+		c.users++
+
+		for _, t := range returns[user] {
+			offset := int(t.Sub(cohortDate) / dur)
+			if offset >= 0 && offset < periods {
+				c.returning[offset]++
+			}
+		}
+	}
+
+	dates := make([]string, 0, len(cohorts))
+	for date := range cohorts {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	matrix := RetentionMatrix{Buckets: buckets, Cohorts: make([]RetentionCohort, 0, len(dates))}
+	for _, date := range dates {
+		c := cohorts[date]
+		returning := make([]float64, periods)
+		for i, count := range c.returning {
+			returning[i] = float64(count) / float64(c.users)
+		}
+		matrix.Cohorts = append(matrix.Cohorts, RetentionCohort{CohortDate: date, CohortSize: c.users, Returning: returning})
+	}
+	return matrix, nil
+}
+
+// GetApiDocs serves the OpenAPI 3 description of the HTTP surface at
+// /openapi.json, built with kin-openapi the same way the external kubviz
+// docs endpoint does it.
+func GetApiDocs(w http.ResponseWriter, r *http.Request) {
+	doc := &openapi3.T{
+		OpenAPI: "3.0.0",
+		Info: &openapi3.Info{
+			Title:   "Owlumn Analytics API",
+			Version: "1.0.0",
+		},
+		Paths: openapi3.Paths{
+			"/track": &openapi3.PathItem{
+				Post: &openapi3.Operation{
+					OperationID: "trackEvent",
+					Responses: openapi3.Responses{
+						"200": &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("event accepted")},
+					},
+				},
+			},
+			"/metrics": &openapi3.PathItem{
+				Get: &openapi3.Operation{
+					OperationID: "getMetricsReport",
+					Responses: openapi3.Responses{
+						"200": &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("metrics report")},
+					},
+				},
+			},
+			"/funnel": &openapi3.PathItem{
+				Get: &openapi3.Operation{
+					OperationID: "getFunnel",
+					Responses: openapi3.Responses{
+						"200": &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("funnel report")},
+						"501": &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("funnel queries not configured")},
+					},
+				},
+			},
+			"/retention": &openapi3.PathItem{
+				Get: &openapi3.Operation{
+					OperationID: "getRetention",
+					Responses: openapi3.Responses{
+						"200": &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("retention matrix")},
+						"501": &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("retention queries not configured")},
+					},
+				},
+			},
+			"/schemas/{eventType}": &openapi3.PathItem{
+				Get: &openapi3.Operation{
+					OperationID: "getSchema",
+					Responses: openapi3.Responses{
+						"200": &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("registered schema")},
+						"404": &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("no schema registered for event type")},
+					},
+				},
+				Post: &openapi3.Operation{
+					OperationID: "putSchema",
+					Responses: openapi3.Responses{
+						"200": &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("schema stored")},
+					},
+				},
+			},
+		},
+	}
+
+	// TrackEventsStream is a gRPC client-streaming RPC (see
+	// proto/analytics.proto), not an HTTP endpoint, so it has no OpenAPI
+	// path of its own; it's noted here in the doc's description instead.
+	doc.Info.Description = "High-throughput batch ingest is also available over gRPC via the TrackEventsStream RPC; see proto/analytics.proto."
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(doc)
+}
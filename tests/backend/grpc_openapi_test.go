@@ -0,0 +1,134 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAnalyticsServiceFromHandler_SharesIngestorAndSchemaValidation(t *testing.T) {
+	storage := NewInMemoryStorage()
+	ingestor := NewAsyncIngestor(storage, IngestorConfig{BufferSize: 10, FlushSize: 500, FlushInterval: 0})
+	defer ingestor.Stop()
+
+	schemaDoc := json.RawMessage(`{"type":"object","required":["amount"]}`)
+	reg := &SchemaRegistry{}
+	assert.NoError(t, reg.Put("purchase", schemaDoc))
+
+	handler := NewAnalyticsHandlerWithIngestor(storage, ingestor)
+	handler.RegisterSchemaRegistry(reg, "admin-secret")
+
+	service := NewAnalyticsServiceFromHandler(handler)
+
+	// Invalid per the registered schema: rejected, exactly as an HTTP
+	// POST /track would be, rather than being saved straight to storage.
+	err := service.TrackEvent(context.Background(), Event{UserID: "u1", EventType: "purchase", Data: `{"currency":"usd"}`})
+	assert.Error(t, err)
+	var validationErr *ValidationFailedError
+	assert.ErrorAs(t, err, &validationErr)
+
+	// Valid: goes through the same ingestor as HTTP TrackEvent calls.
+	err = service.TrackEvent(context.Background(), Event{UserID: "u1", EventType: "purchase", Data: `{"amount":5}`})
+	assert.NoError(t, err)
+	ingestor.Stop()
+
+	metrics, err := service.GetMetricsReport(context.Background(), time.Now().Add(-time.Hour), time.Now().Add(time.Hour), "purchase")
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, 1, metrics[0].Count)
+}
+
+func TestInMemoryStorage_SaveAndGetMetrics(t *testing.T) {
+	storage := NewInMemoryStorage()
+	now := time.Now()
+
+	assert.NoError(t, storage.SaveEvent(Event{UserID: "u1", EventType: "login", Timestamp: now}))
+	assert.NoError(t, storage.SaveEvent(Event{UserID: "u2", EventType: "login", Timestamp: now}))
+	assert.NoError(t, storage.SaveEvent(Event{UserID: "u3", EventType: "signup", Timestamp: now}))
+
+	metrics, err := storage.GetMetrics(now.Add(-time.Hour), now.Add(time.Hour), "login")
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, 2, metrics[0].Count)
+}
+
+func TestInMemoryStorage_GetFunnel_OrderedStepsWithinWindow(t *testing.T) {
+	storage := NewInMemoryStorage()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// u1 completes signup then activate within the window: counts toward
+	// both steps.
+	assert.NoError(t, storage.SaveEvent(Event{UserID: "u1", EventType: "signup", Timestamp: start}))
+	assert.NoError(t, storage.SaveEvent(Event{UserID: "u1", EventType: "activate", Timestamp: start.Add(30 * time.Minute)}))
+
+	// u2 signs up but activates too late, outside the window: counts only
+	// toward the first step.
+	assert.NoError(t, storage.SaveEvent(Event{UserID: "u2", EventType: "signup", Timestamp: start}))
+	assert.NoError(t, storage.SaveEvent(Event{UserID: "u2", EventType: "activate", Timestamp: start.Add(2 * time.Hour)}))
+
+	// u3 never signs up at all: shouldn't count toward anything.
+	assert.NoError(t, storage.SaveEvent(Event{UserID: "u3", EventType: "activate", Timestamp: start}))
+
+	report, err := storage.GetFunnel([]string{"signup", "activate"}, start.Add(-time.Hour), start.Add(3*time.Hour), time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, FunnelReport{Steps: []FunnelStepResult{
+		{Step: "signup", Count: 2, ConversionRate: 1.0},
+		{Step: "activate", Count: 1, ConversionRate: 0.5},
+	}}, report)
+}
+
+func TestInMemoryStorage_GetRetention_BucketsByFirstSeenDate(t *testing.T) {
+	storage := NewInMemoryStorage()
+	day0 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// u1 and u2 are first seen on day0; u1 returns the next day, u2 doesn't.
+	assert.NoError(t, storage.SaveEvent(Event{UserID: "u1", EventType: "signup", Timestamp: day0}))
+	assert.NoError(t, storage.SaveEvent(Event{UserID: "u1", EventType: "login", Timestamp: day0.Add(24 * time.Hour)}))
+	assert.NoError(t, storage.SaveEvent(Event{UserID: "u2", EventType: "signup", Timestamp: day0}))
+
+	// u3 is first seen a day later, and returns that same day.
+	day1 := day0.Add(24 * time.Hour)
+	assert.NoError(t, storage.SaveEvent(Event{UserID: "u3", EventType: "signup", Timestamp: day1}))
+	assert.NoError(t, storage.SaveEvent(Event{UserID: "u3", EventType: "login", Timestamp: day1}))
+
+	matrix, err := storage.GetRetention("signup", "login", day0, day0.Add(48*time.Hour), "day")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0", "1", "2"}, matrix.Buckets)
+	assert.Len(t, matrix.Cohorts, 2)
+
+	assert.Equal(t, "2023-01-01", matrix.Cohorts[0].CohortDate)
+	assert.Equal(t, 2, matrix.Cohorts[0].CohortSize)
+	assert.Equal(t, []float64{0, 0.5, 0}, matrix.Cohorts[0].Returning)
+
+	assert.Equal(t, "2023-01-02", matrix.Cohorts[1].CohortDate)
+	assert.Equal(t, 1, matrix.Cohorts[1].CohortSize)
+	assert.Equal(t, []float64{1, 0, 0}, matrix.Cohorts[1].Returning)
+}
+
+func TestGetApiDocs_ServesOpenAPISpec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(GetApiDocs))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var spec map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&spec)
+	assert.Equal(t, "3.0.0", spec["openapi"])
+
+	paths, _ := spec["paths"].(map[string]interface{})
+	assert.Contains(t, paths, "/track")
+	assert.Contains(t, paths, "/metrics")
+	assert.Contains(t, paths, "/funnel")
+	assert.Contains(t, paths, "/retention")
+	assert.Contains(t, paths, "/schemas/{eventType}")
+}
@@ -0,0 +1,163 @@
+package analytics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Token is a per-API-key checkout: how many calls remain in the current
+// window, the window's limit, and when it resets.
+type Token struct {
+	Key       string
+	Remaining int
+	Limit     int
+	ResetAt   time.Time
+}
+
+// APIKeyStore tracks per-key quota state. CheckOutToken is called once per
+// request to get the current remaining count; UpdateTokenRateLimit is
+// called when the downstream handler reports (via ReportActualRemaining)
+// actual usage that diverges from what the store expected, keeping the
+// two in sync.
+type APIKeyStore interface {
+	CheckOutToken(key string) (*Token, error)
+	UpdateTokenRateLimit(key string, remaining, limit int, resetAt time.Time) error
+}
+
+// TokenPool gates requests by API key, rejecting once a key's quota is
+// exhausted and surfacing the remaining quota on every response.
+type TokenPool struct {
+	store APIKeyStore
+}
+
+func NewTokenPool(store APIKeyStore) *TokenPool {
+	return &TokenPool{store: store}
+}
+
+type contextKey int
+
+const (
+	tokenContextKey contextKey = iota
+	usageReportContextKey
+)
+
+// TokenFromContext returns the Token that TokenPool.Middleware checked out
+// for the current request, or nil if the request didn't go through it.
+// Handlers that need to call ReportActualRemaining use this to find out
+// what the token pool currently expects.
+func TokenFromContext(ctx context.Context) *Token {
+	token, _ := ctx.Value(tokenContextKey).(*Token)
+	return token
+}
+
+// actualUsageReport is the mutable box Middleware stashes in the request
+// context so a downstream handler can hand back the real post-request
+// remaining count before Middleware reconciles the store. It travels via
+// context rather than a response header so it never reaches the client.
+type actualUsageReport struct {
+	remaining int
+	reported  bool
+}
+
+// ReportActualRemaining lets a downstream handler tell TokenPool.Middleware
+// how many calls actually remained after it ran the request, when that
+// differs from what the token pool expects (token.Remaining-1). For
+// example, AnalyticsHandler.TrackEvent calls this when the ingestor
+// rejects an event with ErrIngestorFull: the checked-out token was never
+// really spent, so the pool should give it back. It's a no-op if the
+// request didn't go through Middleware.
+func ReportActualRemaining(ctx context.Context, remaining int) {
+	if report, ok := ctx.Value(usageReportContextKey).(*actualUsageReport); ok {
+		report.remaining = remaining
+		report.reported = true
+	}
+}
+
+// Middleware checks out a token for the request's API key, rejects with
+// 429 when the key is out of quota, and otherwise forwards the request,
+// reconciling the store if the handler reports a different actual usage
+// than what was expected.
+func (p *TokenPool) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if key == "" {
+			http.Error(w, "Missing API key", http.StatusUnauthorized)
+			return
+		}
+
+		token, err := p.store.CheckOutToken(key)
+		if err != nil {
+			http.Error(w, "Invalid API key", http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(token.Remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(token.ResetAt.Unix(), 10))
+
+		if token.Remaining <= 0 {
+			w.Header().Set("Retry-After", strconv.FormatInt(int64(time.Until(token.ResetAt).Seconds()), 10))
+			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+
+		report := &actualUsageReport{}
+		ctx := context.WithValue(r.Context(), tokenContextKey, token)
+		ctx = context.WithValue(ctx, usageReportContextKey, report)
+		next.ServeHTTP(w, r.WithContext(ctx))
+
+		if report.reported && report.remaining != token.Remaining-1 {
+			_ = p.store.UpdateTokenRateLimit(key, report.remaining, token.Limit, token.ResetAt)
+		}
+	})
+}
+
+// InMemoryAPIKeyStore is a process-local APIKeyStore giving every key the
+// same fixed quota per fixed window, resetting once the window elapses.
+// It's meant for single-instance deployments; a multi-instance deployment
+// needs a shared backing store (e.g. Redis) to enforce quotas across
+// replicas.
+type InMemoryAPIKeyStore struct {
+	limit  int
+	window time.Duration
+
+	mu     sync.Mutex
+	tokens map[string]*Token
+}
+
+func NewInMemoryAPIKeyStore(limit int, window time.Duration) *InMemoryAPIKeyStore {
+	return &InMemoryAPIKeyStore{
+		limit:  limit,
+		window: window,
+		tokens: make(map[string]*Token),
+	}
+}
+
+func (s *InMemoryAPIKeyStore) CheckOutToken(key string) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[key]
+	if !ok || !time.Now().Before(token.ResetAt) {
+		token = &Token{Key: key, Remaining: s.limit, Limit: s.limit, ResetAt: time.Now().Add(s.window)}
+		s.tokens[key] = token
+	}
+
+	current := *token
+	if token.Remaining > 0 {
+		token.Remaining--
+	}
+	return &current, nil
+}
+
+func (s *InMemoryAPIKeyStore) UpdateTokenRateLimit(key string, remaining, limit int, resetAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if token, ok := s.tokens[key]; ok {
+		token.Remaining = remaining
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package analytics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockAPIKeyStore is a hand-rolled gomock-style mock matching the pattern
+// used for MockAnalyticsStorage elsewhere in this package.
+type mockAPIKeyStore struct {
+	ctrl     *gomock.Controller
+	recorder *mockAPIKeyStoreRecorder
+}
+
+type mockAPIKeyStoreRecorder struct {
+	mock *mockAPIKeyStore
+}
+
+func newMockAPIKeyStore(ctrl *gomock.Controller) *mockAPIKeyStore {
+	mock := &mockAPIKeyStore{ctrl: ctrl}
+	mock.recorder = &mockAPIKeyStoreRecorder{mock}
+	return mock
+}
+
+func (m *mockAPIKeyStore) EXPECT() *mockAPIKeyStoreRecorder {
+	return m.recorder
+}
+
+func (m *mockAPIKeyStore) CheckOutToken(key string) (*Token, error) {
+	results := m.ctrl.Call(m, "CheckOutToken", key)
+	token, _ := results[0].(*Token)
+	err, _ := results[1].(error)
+	return token, err
+}
+
+func (m *mockAPIKeyStoreRecorder) CheckOutToken(key interface{}) *gomock.Call {
+	return m.mock.ctrl.RecordCall(m.mock, "CheckOutToken", key)
+}
+
+func (m *mockAPIKeyStore) UpdateTokenRateLimit(key string, remaining, limit int, resetAt time.Time) error {
+	results := m.ctrl.Call(m, "UpdateTokenRateLimit", key, remaining, limit, resetAt)
+	err, _ := results[0].(error)
+	return err
+}
+
+func (m *mockAPIKeyStoreRecorder) UpdateTokenRateLimit(key, remaining, limit, resetAt interface{}) *gomock.Call {
+	return m.mock.ctrl.RecordCall(m.mock, "UpdateTokenRateLimit", key, remaining, limit, resetAt)
+}
+
+func TestTokenPool_Middleware_ChecksOutAndForwards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	resetAt := time.Now().Add(time.Minute)
+	store := newMockAPIKeyStore(ctrl)
+	store.EXPECT().CheckOutToken("key1").Return(&Token{Key: "key1", Remaining: 5, Limit: 100, ResetAt: resetAt}, nil)
+
+	pool := NewTokenPool(store)
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := httptest.NewServer(pool.Middleware(next))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("X-API-Key", "key1")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.True(t, called)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "5", resp.Header.Get("X-RateLimit-Remaining"))
+}
+
+func TestTokenPool_Middleware_MissingAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockAPIKeyStore(ctrl)
+	pool := NewTokenPool(store)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next handler should not be called without an API key")
+	})
+
+	server := httptest.NewServer(pool.Middleware(next))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestTokenPool_Middleware_QuotaExhausted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	resetAt := time.Now().Add(30 * time.Second)
+	store := newMockAPIKeyStore(ctrl)
+	store.EXPECT().CheckOutToken("key1").Return(&Token{Key: "key1", Remaining: 0, Limit: 100, ResetAt: resetAt}, nil)
+
+	pool := NewTokenPool(store)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next handler should not be called when quota is exhausted")
+	})
+
+	server := httptest.NewServer(pool.Middleware(next))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("X-API-Key", "key1")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+}
+
+func TestTokenPool_Middleware_ReconcilesDivergedUsage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	resetAt := time.Now().Add(time.Minute)
+	store := newMockAPIKeyStore(ctrl)
+	store.EXPECT().CheckOutToken("key1").Return(&Token{Key: "key1", Remaining: 5, Limit: 100, ResetAt: resetAt}, nil)
+	store.EXPECT().UpdateTokenRateLimit("key1", 2, 100, resetAt).Return(nil)
+
+	pool := NewTokenPool(store)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Downstream reports it actually consumed more quota than expected,
+		// e.g. because of a batched call. This travels via the request
+		// context, never the response, so it can't leak to the client.
+		ReportActualRemaining(r.Context(), 2)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := httptest.NewServer(pool.Middleware(next))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("X-API-Key", "key1")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("X-Actual-Remaining"))
+}
+
+func TestTokenPool_Middleware_InvalidAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockAPIKeyStore(ctrl)
+	store.EXPECT().CheckOutToken("bogus").Return(nil, assert.AnError)
+
+	pool := NewTokenPool(store)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next handler should not be called for an invalid key")
+	})
+
+	server := httptest.NewServer(pool.Middleware(next))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("X-API-Key", "bogus")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestInMemoryAPIKeyStore_DecrementsAndResets(t *testing.T) {
+	store := NewInMemoryAPIKeyStore(2, 50*time.Millisecond)
+
+	first, err := store.CheckOutToken("key1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, first.Remaining)
+
+	second, err := store.CheckOutToken("key1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, second.Remaining)
+
+	third, err := store.CheckOutToken("key1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, third.Remaining)
+
+	time.Sleep(60 * time.Millisecond)
+
+	afterReset, err := store.CheckOutToken("key1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, afterReset.Remaining)
+}
+
+func TestInMemoryAPIKeyStore_UpdateTokenRateLimitReconciles(t *testing.T) {
+	store := NewInMemoryAPIKeyStore(5, time.Minute)
+
+	_, err := store.CheckOutToken("key1")
+	assert.NoError(t, err)
+	assert.NoError(t, store.UpdateTokenRateLimit("key1", 1, 5, time.Now().Add(time.Minute)))
+
+	next, err := store.CheckOutToken("key1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, next.Remaining)
+}
+
+// TestTokenPool_Middleware_RefundsRejectedIngest exercises the real call
+// site: AnalyticsHandler.TrackEvent reports the checked-out token back as
+// unspent when the async ingestor rejects the event with ErrIngestorFull,
+// so the pool doesn't charge the caller's quota for work that never
+// happened.
+func TestTokenPool_Middleware_RefundsRejectedIngest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	resetAt := time.Now().Add(time.Minute)
+	store := newMockAPIKeyStore(ctrl)
+	store.EXPECT().CheckOutToken("key1").Return(&Token{Key: "key1", Remaining: 5, Limit: 100, ResetAt: resetAt}, nil)
+	store.EXPECT().UpdateTokenRateLimit("key1", 5, 100, resetAt).Return(nil)
+
+	mockStorage := newMockBatchAnalyticsStorage(ctrl)
+	cfg := IngestorConfig{BufferSize: 0, FlushSize: 1000, FlushInterval: time.Minute}
+	ingestor := NewAsyncIngestor(mockStorage, cfg)
+	defer ingestor.Stop()
+
+	handler := NewAnalyticsHandlerWithIngestor(nil, ingestor)
+	pool := NewTokenPool(store)
+	server := httptest.NewServer(pool.Middleware(http.HandlerFunc(handler.TrackEvent)))
+	defer server.Close()
+
+	body := strings.NewReader(`{"user_id":"u1","event_type":"login","data":"x"}`)
+	req, _ := http.NewRequest(http.MethodPost, server.URL, body)
+	req.Header.Set("X-API-Key", "key1")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
@@ -0,0 +1,251 @@
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// SchemaSource loads raw JSON Schema documents keyed by event type. Concrete
+// implementations read from disk or fetch from a URL; SchemaRegistry only
+// depends on this interface so either can be swapped in at startup.
+type SchemaSource interface {
+	Load() (map[string]json.RawMessage, error)
+}
+
+// DiskSchemaSource loads one schema per *.json file in Dir, using the file
+// name (without extension) as the event type.
+type DiskSchemaSource struct {
+	Dir string
+}
+
+func (s DiskSchemaSource) Load() (map[string]json.RawMessage, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	schemas := make(map[string]json.RawMessage)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.Dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		eventType := strings.TrimSuffix(entry.Name(), ".json")
+		schemas[eventType] = data
+	}
+	return schemas, nil
+}
+
+// URLSchemaSource loads schemas from a single manifest document served at
+// URL: a JSON object mapping event type to its raw schema, in the same
+// shape DiskSchemaSource builds from a directory. This lets a deployment
+// point at a schema registry service instead of shipping schema files with
+// the binary.
+type URLSchemaSource struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s URLSchemaSource) Load() (map[string]json.RawMessage, error) {
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	resp, err := client.Get(s.URL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching schema manifest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching schema manifest: unexpected status %d", resp.StatusCode)
+	}
+
+	var schemas map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&schemas); err != nil {
+		return nil, fmt.Errorf("decoding schema manifest: %w", err)
+	}
+	return schemas, nil
+}
+
+// ValidationError is one field-level failure from validating Event.Data
+// against its registered schema.
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// SchemaRegistry holds compiled JSON Schemas per event type and validates
+// incoming event payloads against them. It can be reloaded at any time
+// (e.g. on SIGHUP) without interrupting in-flight validations.
+type SchemaRegistry struct {
+	source SchemaSource
+
+	mu      sync.RWMutex
+	raw     map[string]json.RawMessage
+	schemas map[string]*gojsonschema.Schema
+}
+
+// NewSchemaRegistry loads the initial schema set from source.
+func NewSchemaRegistry(source SchemaSource) (*SchemaRegistry, error) {
+	reg := &SchemaRegistry{source: source}
+	if err := reg.Reload(); err != nil {
+		return nil, err
+	}
+	return reg, nil
+}
+
+// Reload re-reads every schema from the source and atomically swaps them
+// in. Call this from a SIGHUP handler to pick up schema changes without a
+// restart.
+func (r *SchemaRegistry) Reload() error {
+	raw, err := r.source.Load()
+	if err != nil {
+		return err
+	}
+
+	compiled := make(map[string]*gojsonschema.Schema, len(raw))
+	for eventType, doc := range raw {
+		schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(doc))
+		if err != nil {
+			return fmt.Errorf("compiling schema for %q: %w", eventType, err)
+		}
+		compiled[eventType] = schema
+	}
+
+	r.mu.Lock()
+	r.raw = raw
+	r.schemas = compiled
+	r.mu.Unlock()
+	return nil
+}
+
+// Put compiles and registers a single schema, used by the admin endpoint so
+// a schema can be added without a full Reload from the source.
+func (r *SchemaRegistry) Put(eventType string, doc json.RawMessage) error {
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(doc))
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.raw == nil {
+		r.raw = make(map[string]json.RawMessage)
+		r.schemas = make(map[string]*gojsonschema.Schema)
+	}
+	r.raw[eventType] = doc
+	r.schemas[eventType] = schema
+	return nil
+}
+
+// Get returns the raw schema document registered for eventType, if any.
+func (r *SchemaRegistry) Get(eventType string) (json.RawMessage, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	doc, ok := r.raw[eventType]
+	return doc, ok
+}
+
+// Validate checks data against the schema registered for eventType. It
+// returns (nil, nil) when no schema is registered for that type, since
+// schema validation is opt-in per event type.
+func (r *SchemaRegistry) Validate(eventType string, data []byte) ([]ValidationError, error) {
+	r.mu.RLock()
+	schema, ok := r.schemas[eventType]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		return nil, err
+	}
+	if result.Valid() {
+		return nil, nil
+	}
+
+	errs := make([]ValidationError, 0, len(result.Errors()))
+	for _, e := range result.Errors() {
+		errs = append(errs, ValidationError{Field: e.Field(), Message: e.Description()})
+	}
+	return errs, nil
+}
+
+// WatchSIGHUP reloads the registry from its source every time the process
+// receives SIGHUP, so schema changes on disk can be picked up without a
+// restart. It returns immediately; reloading happens on a background
+// goroutine for the lifetime of the process.
+func (r *SchemaRegistry) WatchSIGHUP() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	go func() {
+		for range sigCh {
+			_ = r.Reload()
+		}
+	}()
+}
+
+// RegisterSchemaRegistry wires schema validation into TrackEvent and enables
+// the /schemas/{eventType} admin endpoints, guarded by adminToken.
+func (h *AnalyticsHandler) RegisterSchemaRegistry(registry *SchemaRegistry, adminToken string) {
+	h.schemaRegistry = registry
+	h.adminToken = adminToken
+}
+
+// SchemaAdmin serves GET and POST /schemas/{eventType}, requiring the
+// X-Admin-Token header to match the configured admin token.
+func (h *AnalyticsHandler) SchemaAdmin(w http.ResponseWriter, r *http.Request) {
+	if h.schemaRegistry == nil {
+		http.Error(w, "Schema registry not configured", http.StatusNotImplemented)
+		return
+	}
+	if h.adminToken == "" || r.Header.Get("X-Admin-Token") != h.adminToken {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	eventType := strings.TrimPrefix(r.URL.Path, "/schemas/")
+	if eventType == "" {
+		http.Error(w, "Missing event type", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		doc, ok := h.schemaRegistry.Get(eventType)
+		if !ok {
+			http.Error(w, "No schema registered for event type", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(doc)
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		if err := h.schemaRegistry.Put(eventType, body); err != nil {
+			http.Error(w, "Invalid JSON Schema", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,167 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLSchemaSource_LoadsManifest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"purchase":{"type":"object"}}`))
+	}))
+	defer server.Close()
+
+	source := URLSchemaSource{URL: server.URL}
+	schemas, err := source.Load()
+	assert.NoError(t, err)
+	assert.Contains(t, schemas, "purchase")
+}
+
+func TestURLSchemaSource_NonOKStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	source := URLSchemaSource{URL: server.URL}
+	_, err := source.Load()
+	assert.Error(t, err)
+}
+
+func TestAnalyticsHandler_TrackEvent_RejectsNonJSONDataAs422(t *testing.T) {
+	schemaDoc := json.RawMessage(`{"type":"object"}`)
+	reg := &SchemaRegistry{}
+	assert.NoError(t, reg.Put("purchase", schemaDoc))
+
+	handler := NewAnalyticsHandler(noopStorage{})
+	handler.RegisterSchemaRegistry(reg, "admin-secret")
+
+	server := httptest.NewServer(http.HandlerFunc(handler.TrackEvent))
+	defer server.Close()
+
+	event := Event{UserID: "u1", EventType: "purchase", Data: "not json"}
+	body, _ := json.Marshal(event)
+
+	resp, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+}
+
+func TestSchemaRegistry_ValidatesSuccessfully(t *testing.T) {
+	schemaDoc := json.RawMessage(`{"type":"object","required":["amount"],"properties":{"amount":{"type":"number"}}}`)
+	reg := &SchemaRegistry{}
+	assert.NoError(t, reg.Put("purchase", schemaDoc))
+
+	errs, err := reg.Validate("purchase", []byte(`{"amount":42}`))
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+}
+
+func TestSchemaRegistry_ReturnsStructuredErrorsOnInvalidPayload(t *testing.T) {
+	schemaDoc := json.RawMessage(`{"type":"object","required":["amount"],"properties":{"amount":{"type":"number"}}}`)
+	reg := &SchemaRegistry{}
+	assert.NoError(t, reg.Put("purchase", schemaDoc))
+
+	errs, err := reg.Validate("purchase", []byte(`{"amount":"not-a-number"}`))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, errs)
+}
+
+func TestSchemaRegistry_UnknownEventTypeSkipsValidation(t *testing.T) {
+	reg := &SchemaRegistry{}
+	errs, err := reg.Validate("unknown", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+}
+
+func TestAnalyticsHandler_TrackEvent_RejectsInvalidPayload(t *testing.T) {
+	schemaDoc := json.RawMessage(`{"type":"object","required":["amount"]}`)
+	reg := &SchemaRegistry{}
+	assert.NoError(t, reg.Put("purchase", schemaDoc))
+
+	ingestor := NewAsyncIngestor(noopBatchStorage{}, IngestorConfig{BufferSize: 10, FlushSize: 500, FlushInterval: 0})
+	defer ingestor.Stop()
+
+	handler := NewAnalyticsHandlerWithIngestor(nil, ingestor)
+	handler.RegisterSchemaRegistry(reg, "admin-secret")
+
+	server := httptest.NewServer(http.HandlerFunc(handler.TrackEvent))
+	defer server.Close()
+
+	event := Event{UserID: "u1", EventType: "purchase", Data: `{"currency":"usd"}`}
+	body, _ := json.Marshal(event)
+
+	resp, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	var payload struct {
+		Errors []ValidationError `json:"errors"`
+	}
+	json.NewDecoder(resp.Body).Decode(&payload)
+	assert.NotEmpty(t, payload.Errors)
+}
+
+func TestSchemaAdmin_RejectsWithoutAdminToken(t *testing.T) {
+	reg := &SchemaRegistry{}
+	handler := NewAnalyticsHandler(noopStorage{})
+	handler.RegisterSchemaRegistry(reg, "admin-secret")
+
+	server := httptest.NewServer(http.HandlerFunc(handler.SchemaAdmin))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/schemas/purchase")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestSchemaAdmin_PutThenGet(t *testing.T) {
+	reg := &SchemaRegistry{}
+	handler := NewAnalyticsHandler(noopStorage{})
+	handler.RegisterSchemaRegistry(reg, "admin-secret")
+
+	server := httptest.NewServer(http.HandlerFunc(handler.SchemaAdmin))
+	defer server.Close()
+
+	schemaDoc := `{"type":"object"}`
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/schemas/purchase", strings.NewReader(schemaDoc))
+	req.Header.Set("X-Admin-Token", "admin-secret")
+	putResp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer putResp.Body.Close()
+	assert.Equal(t, http.StatusOK, putResp.StatusCode)
+
+	getReq, _ := http.NewRequest(http.MethodGet, server.URL+"/schemas/purchase", nil)
+	getReq.Header.Set("X-Admin-Token", "admin-secret")
+	getResp, err := http.DefaultClient.Do(getReq)
+	assert.NoError(t, err)
+	defer getResp.Body.Close()
+	assert.Equal(t, http.StatusOK, getResp.StatusCode)
+}
+
+// noopStorage and noopBatchStorage satisfy AnalyticsStorage /
+// BatchAnalyticsStorage for tests in this file that only exercise schema
+// validation and don't care about persistence.
+type noopStorage struct{}
+
+func (noopStorage) SaveEvent(event Event) error { return nil }
+func (noopStorage) GetMetrics(start, end time.Time, eventType string) ([]Metric, error) {
+	return nil, nil
+}
+
+type noopBatchStorage struct{}
+
+func (noopBatchStorage) SaveEvents(events []Event) error { return nil }
@@ -0,0 +1,176 @@
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Sink is a fan-out publish target for accepted events. It is modeled after
+// the NATSClientInterface pattern: a thin Publish(subject, data) contract
+// that concrete transports (NATS, Kafka, webhooks) implement, so the
+// handler never depends on a specific broker client.
+type Sink interface {
+	Publish(subject string, data []byte) error
+}
+
+// SubjectTemplate renders the publish subject for an event. The default
+// groups events by type, e.g. "events.login".
+func SubjectTemplate(event Event) string {
+	return fmt.Sprintf("events.%s", event.EventType)
+}
+
+// RegisterSink adds a fan-out sink. Sinks are published to after the
+// primary storage write and a failure on one sink never affects another or
+// the response already sent to the caller.
+func (h *AnalyticsHandler) RegisterSink(sink Sink) {
+	h.sinks = append(h.sinks, sink)
+}
+
+// publishToSinks fans the event out to every registered sink. It is called
+// after the primary write succeeds and runs each publish independently so a
+// slow or failing sink can't block the others.
+func (h *AnalyticsHandler) publishToSinks(event Event) {
+	if len(h.sinks) == 0 {
+		return
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	subject := SubjectTemplate(event)
+
+	var wg sync.WaitGroup
+	wg.Add(len(h.sinks))
+	for _, sink := range h.sinks {
+		go func(s Sink) {
+			defer wg.Done()
+			_ = s.Publish(subject, data)
+		}(sink)
+	}
+	wg.Wait()
+}
+
+// NATSClientInterface is the minimal surface of a NATS JetStream client
+// this package depends on, kept narrow so it's trivial to mock.
+type NATSClientInterface interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSSink publishes events to a NATS subject derived from the event type.
+type NATSSink struct {
+	client NATSClientInterface
+}
+
+func NewNATSSink(client NATSClientInterface) *NATSSink {
+	return &NATSSink{client: client}
+}
+
+func (s *NATSSink) Publish(subject string, data []byte) error {
+	return s.client.Publish(subject, data)
+}
+
+// KafkaProducer is the minimal surface of a Kafka producer this package
+// depends on.
+type KafkaProducer interface {
+	Publish(subject string, data []byte) error
+}
+
+// KafkaSink publishes events to a Kafka topic derived from the event type.
+type KafkaSink struct {
+	producer KafkaProducer
+}
+
+func NewKafkaSink(producer KafkaProducer) *KafkaSink {
+	return &KafkaSink{producer: producer}
+}
+
+func (s *KafkaSink) Publish(subject string, data []byte) error {
+	return s.producer.Publish(subject, data)
+}
+
+// WebhookPublisher is the minimal surface of an HTTP client this package
+// depends on for webhook delivery.
+type WebhookPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// WebhookSink delivers events to an externally configured HTTP endpoint.
+type WebhookSink struct {
+	publisher WebhookPublisher
+}
+
+func NewWebhookSink(publisher WebhookPublisher) *WebhookSink {
+	return &WebhookSink{publisher: publisher}
+}
+
+func (s *WebhookSink) Publish(subject string, data []byte) error {
+	return s.publisher.Publish(subject, data)
+}
+
+// RetrySink wraps another Sink with exponential backoff, retrying a failed
+// publish up to MaxAttempts times before giving up.
+type RetrySink struct {
+	inner       Sink
+	MaxAttempts int
+	BaseDelay   time.Duration
+}
+
+func NewRetrySink(inner Sink, maxAttempts int, baseDelay time.Duration) *RetrySink {
+	return &RetrySink{inner: inner, MaxAttempts: maxAttempts, BaseDelay: baseDelay}
+}
+
+func (s *RetrySink) Publish(subject string, data []byte) error {
+	var err error
+	for attempt := 0; attempt < s.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(s.BaseDelay * time.Duration(1<<uint(attempt-1)))
+		}
+		if err = s.inner.Publish(subject, data); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// DLQSink wraps another Sink and records publishes that exhaust retries so
+// they can be inspected or replayed later, instead of being dropped.
+type DLQSink struct {
+	inner Sink
+
+	mu     sync.Mutex
+	failed []FailedPublish
+}
+
+// FailedPublish is a publish attempt that the wrapped sink could not
+// complete.
+type FailedPublish struct {
+	Subject string
+	Data    []byte
+	Err     error
+}
+
+func NewDLQSink(inner Sink) *DLQSink {
+	return &DLQSink{inner: inner}
+}
+
+func (s *DLQSink) Publish(subject string, data []byte) error {
+	if err := s.inner.Publish(subject, data); err != nil {
+		s.mu.Lock()
+		s.failed = append(s.failed, FailedPublish{Subject: subject, Data: data, Err: err})
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Failed returns a copy of the publishes that were sent to the dead-letter
+// queue.
+func (s *DLQSink) Failed() []FailedPublish {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]FailedPublish, len(s.failed))
+	copy(out, s.failed)
+	return out
+}
@@ -0,0 +1,132 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockSink is a hand-rolled gomock-style mock matching the pattern used for
+// MockAnalyticsStorage elsewhere in this package.
+type mockSink struct {
+	ctrl     *gomock.Controller
+	recorder *mockSinkRecorder
+}
+
+type mockSinkRecorder struct {
+	mock *mockSink
+}
+
+func newMockSink(ctrl *gomock.Controller) *mockSink {
+	mock := &mockSink{ctrl: ctrl}
+	mock.recorder = &mockSinkRecorder{mock}
+	return mock
+}
+
+func (m *mockSink) EXPECT() *mockSinkRecorder {
+	return m.recorder
+}
+
+func (m *mockSink) Publish(subject string, data []byte) error {
+	results := m.ctrl.Call(m, "Publish", subject, data)
+	err, _ := results[0].(error)
+	return err
+}
+
+func (m *mockSinkRecorder) Publish(subject, data interface{}) *gomock.Call {
+	return m.mock.ctrl.RecordCall(m.mock, "Publish", subject, data)
+}
+
+func TestAnalyticsHandler_PublishToSinks_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sinkA := newMockSink(ctrl)
+	sinkA.EXPECT().Publish("events.login", gomock.Any()).Return(nil)
+	sinkB := newMockSink(ctrl)
+	sinkB.EXPECT().Publish("events.login", gomock.Any()).Return(nil)
+
+	mockStorage := NewMockAnalyticsStorage(ctrl)
+	handler := NewAnalyticsHandler(mockStorage)
+	handler.RegisterSink(sinkA)
+	handler.RegisterSink(sinkB)
+
+	handler.publishToSinks(Event{UserID: "u1", EventType: "login"})
+}
+
+func TestAnalyticsHandler_PublishToSinks_OneSinkErrorDoesNotBlockOthers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	failing := newMockSink(ctrl)
+	failing.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(assert.AnError)
+	healthy := newMockSink(ctrl)
+	healthy.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+
+	mockStorage := NewMockAnalyticsStorage(ctrl)
+	handler := NewAnalyticsHandler(mockStorage)
+	handler.RegisterSink(failing)
+	handler.RegisterSink(healthy)
+
+	// Must not panic or block even though one sink errors.
+	handler.publishToSinks(Event{UserID: "u1", EventType: "signup"})
+}
+
+func TestRetrySink_SucceedsAfterTransientFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := newMockSink(ctrl)
+	gomock.InOrder(
+		inner.EXPECT().Publish("events.login", gomock.Any()).Return(assert.AnError),
+		inner.EXPECT().Publish("events.login", gomock.Any()).Return(assert.AnError),
+		inner.EXPECT().Publish("events.login", gomock.Any()).Return(nil),
+	)
+
+	retry := NewRetrySink(inner, 3, time.Millisecond)
+	err := retry.Publish("events.login", []byte(`{}`))
+	assert.NoError(t, err)
+}
+
+func TestRetrySink_ReturnsLastErrorAfterExhaustingAttempts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := newMockSink(ctrl)
+	inner.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(assert.AnError).Times(3)
+
+	retry := NewRetrySink(inner, 3, time.Millisecond)
+	err := retry.Publish("events.login", []byte(`{}`))
+	assert.Equal(t, assert.AnError, err)
+}
+
+func TestDLQSink_RecordsFailedPublishes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := newMockSink(ctrl)
+	inner.EXPECT().Publish("events.login", gomock.Any()).Return(assert.AnError)
+
+	dlq := NewDLQSink(inner)
+	err := dlq.Publish("events.login", []byte(`{"user_id":"u1"}`))
+	assert.Equal(t, assert.AnError, err)
+
+	failed := dlq.Failed()
+	assert.Len(t, failed, 1)
+	assert.Equal(t, "events.login", failed[0].Subject)
+}
+
+func TestDLQSink_DoesNotRecordSuccessfulPublishes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := newMockSink(ctrl)
+	inner.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+
+	dlq := NewDLQSink(inner)
+	err := dlq.Publish("events.login", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Empty(t, dlq.Failed())
+}